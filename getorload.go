@@ -0,0 +1,70 @@
+package lrucache
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// loadWaiter coordinates the goroutines racing to load the same key: the
+// first one runs the loader, the rest block on done and share its result.
+type loadWaiter struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// GetOrLoad returns the cached value for key, or, on a miss, calls loader to
+// produce one. If several goroutines call GetOrLoad for the same key
+// concurrently while it is missing, loader runs exactly once and every
+// caller receives its result, following the singleflight pattern. The TTL
+// returned by loader is passed to Store; on error, nothing is cached and
+// every waiter receives the error.
+func (c *cache) GetOrLoad(key string, loader func() ([]byte, time.Duration, error)) (io.Reader, error) {
+	if r, err := c.Get(key); err == nil {
+		return r, nil
+	}
+
+	c.loadM.Lock()
+	if w, ok := c.loadWaiters[key]; ok {
+		c.loadM.Unlock()
+		<-w.done
+		if w.err != nil {
+			return nil, w.err
+		}
+		return bytes.NewReader(w.data), nil
+	}
+
+	w := &loadWaiter{done: make(chan struct{})}
+	c.loadWaiters[key] = w
+	c.loadM.Unlock()
+
+	data, ttl, err := loader()
+
+	c.loadM.Lock()
+	delete(c.loadWaiters, key)
+	c.loadM.Unlock()
+
+	if err == nil {
+		// ttl == 0 means "use the cache's configured default", which Store
+		// only does when exp is omitted entirely; passing 0 through as an
+		// explicit exp would make the entry expire at the end of the current
+		// second instead of after DefaultExpire.
+		if ttl > 0 {
+			err = c.Store(key, data, ttl)
+		} else {
+			err = c.Store(key, data)
+		}
+	}
+
+	// Resolve all waiters to the same outcome we're about to return: either
+	// both see the stored data, or both see the same error.
+	if err != nil {
+		w.err = err
+		close(w.done)
+		return nil, err
+	}
+	w.data = data
+	close(w.done)
+	return bytes.NewReader(data), nil
+}
@@ -0,0 +1,90 @@
+package lrucache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadCollapsesConcurrentMisses(t *testing.T) {
+	c := New(Configuration{MaxSize: 1024})
+
+	var calls int32
+	loader := func() ([]byte, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return []byte("v"), time.Minute, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := c.GetOrLoad("k", loader)
+			if err != nil {
+				t.Errorf("GetOrLoad: %v", err)
+				return
+			}
+			buf := make([]byte, 1)
+			r.Read(buf)
+			if string(buf) != "v" {
+				t.Errorf("got %q, want %q", buf, "v")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("loader called %d times, want 1", got)
+	}
+}
+
+// TestGetOrLoadZeroTTLUsesDefault reproduces a bug where a loader-returned
+// ttl of 0 (meaning "no explicit TTL, use the cache default") was passed
+// through to Store as an explicit exp of 0, which makes the entry expire at
+// the end of the current second instead of after DefaultExpire.
+func TestGetOrLoadZeroTTLUsesDefault(t *testing.T) {
+	c := New(Configuration{MaxSize: 1024, DefaultExpire: time.Hour})
+
+	_, err := c.GetOrLoad("k", func() ([]byte, time.Duration, error) {
+		return []byte("v"), 0, nil
+	})
+	if err != nil {
+		t.Fatalf("GetOrLoad: %v", err)
+	}
+
+	if _, err := c.Get("k"); err != nil {
+		t.Fatalf("Get after zero-TTL load: %v", err)
+	}
+	if exp := c.elements["k"].exp; exp < time.Now().Add(time.Minute).Unix() {
+		t.Fatalf("exp = %d, want about DefaultExpire (an hour) from now", exp)
+	}
+}
+
+func TestGetOrLoadErrorPropagatesToAllWaiters(t *testing.T) {
+	c := New(Configuration{MaxSize: 1024})
+	wantErr := errors.New("boom")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.GetOrLoad("k", func() ([]byte, time.Duration, error) {
+				time.Sleep(5 * time.Millisecond)
+				return nil, 0, wantErr
+			})
+			if err != wantErr {
+				t.Errorf("got %v, want %v", err, wantErr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := c.Get("k"); err != ErrElementNotfound {
+		t.Fatalf("Get after failed load: got %v, want ErrElementNotfound", err)
+	}
+}
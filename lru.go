@@ -2,7 +2,6 @@ package lrucache
 
 import (
 	"bytes"
-	"container/list"
 	"errors"
 	"io"
 	"runtime"
@@ -12,32 +11,89 @@ import (
 
 var (
 	ErrElementNotfound = errors.New("element not found")
+	// ErrCacheFull is returned by Store when pinned entries leave no room to
+	// evict enough space for the new blob.
+	ErrCacheFull = errors.New("cache full")
+	// ErrCacheItemTooLarge is returned by Store when a single blob is larger
+	// than MaxSize, so no amount of eviction would ever make it fit.
+	ErrCacheItemTooLarge = errors.New("cache item too large")
 )
 
 type cacheElem struct {
+	key    string
+	blob   []byte
+	exp    int64
+	pinned int
+}
+
+// expiringElem is a point-in-time snapshot of an expired cacheElem's
+// immutable-for-the-duration-of-this-sweep fields, taken while c.m is held,
+// so mrproper can safely read key/blob after releasing the lock to call
+// OnExpired. ptr is kept only to detect, once the lock is reacquired,
+// whether the entry is still the same one (it may have been replaced or
+// removed concurrently by Store/Get).
+type expiringElem struct {
+	ptr  *cacheElem
 	key  string
 	blob []byte
-	exp  int64
 }
 
 type cache struct {
-	elements      map[string]*list.Element
-	recency       *list.List
+	elements      map[string]*cacheElem
+	pol           policy
 	m             *sync.Mutex
 	size, maxSize int
 	defaultExpire time.Duration
 	done          chan struct{}
+	callbacks     Callbacks
+
+	loadM       sync.Mutex
+	loadWaiters map[string]*loadWaiter
 }
 
-// Store data using key. Optionally, the element will expire after exp
-func (c *cache) Store(key string, data []byte, exp ...time.Duration) {
+// Store data using key. Optionally, the element will expire after exp.
+// It returns ErrCacheItemTooLarge if data alone exceeds MaxSize, or
+// ErrCacheFull if pinned entries leave no room to evict enough space.
+func (c *cache) Store(key string, data []byte, exp ...time.Duration) error {
 	c.m.Lock()
-	defer c.m.Unlock()
-	for c.size+len(data) > c.maxSize {
-		del := c.recency.Back()
-		c.recency.Remove(del)
-		delete(c.elements, del.Value.(cacheElem).key)
-		c.size -= len(del.Value.(cacheElem).blob)
+
+	if len(data) > c.maxSize {
+		c.m.Unlock()
+		return ErrCacheItemTooLarge
+	}
+
+	existing, isUpdate := c.elements[key]
+	addSize := len(data)
+	if isUpdate {
+		addSize -= len(existing.blob)
+	}
+
+	// Decide which keys would need to go before touching any state: if
+	// pinned entries mean there's never enough to evict, Store must fail
+	// without having already thrown away anything.
+	excluded := make(map[string]bool)
+	var toEvict []string
+	freed := 0
+	for c.size-freed+addSize > c.maxSize {
+		victim, ok := c.pol.victim(func(k string) bool {
+			return k != key && !excluded[k] && c.elements[k].pinned == 0
+		})
+		if !ok {
+			c.m.Unlock()
+			return ErrCacheFull
+		}
+		excluded[victim] = true
+		toEvict = append(toEvict, victim)
+		freed += len(c.elements[victim].blob)
+	}
+
+	var evicted []*cacheElem
+	for _, victim := range toEvict {
+		e := c.elements[victim]
+		delete(c.elements, victim)
+		c.pol.evicted(victim)
+		c.size -= len(e.blob)
+		evicted = append(evicted, e)
 	}
 
 	expTime := time.Now().Add(c.defaultExpire).Unix()
@@ -45,29 +101,90 @@ func (c *cache) Store(key string, data []byte, exp ...time.Duration) {
 		expTime = time.Now().Add(exp[0]).Unix()
 	}
 
-	elem := c.recency.PushFront(cacheElem{
-		key:  key,
-		blob: data,
-		exp:  expTime,
-	})
-	c.elements[key] = elem
+	if isUpdate {
+		existing.blob = data
+		existing.exp = expTime
+		c.pol.touch(key, len(data))
+	} else {
+		c.elements[key] = &cacheElem{key: key, blob: data, exp: expTime}
+		c.pol.insert(key, len(data))
+	}
+	c.size += addSize
+	c.m.Unlock()
+
+	for _, e := range evicted {
+		c.fireEvicted(e)
+	}
+	if c.callbacks.OnAfterPut != nil {
+		c.callbacks.OnAfterPut(key, data)
+	}
+	return nil
+}
+
+func (c *cache) fireEvicted(e *cacheElem) {
+	if c.callbacks.OnEvicted != nil {
+		c.callbacks.OnEvicted(e.key, e.blob)
+	}
 }
 
 // Get returns an io.Reader for the data associated with key
 func (c *cache) Get(key string) (io.Reader, error) {
 	c.m.Lock()
-	defer c.m.Unlock()
-	if elem, ok := c.elements[key]; ok {
-		if elem.Value.(cacheElem).exp > 0 && elem.Value.(cacheElem).exp < time.Now().Unix() {
-			delete(c.elements, elem.Value.(cacheElem).key)
-			c.recency.Remove(elem)
-			c.size -= len(elem.Value.(cacheElem).blob)
-			return nil, ErrElementNotfound
+	e, ok := c.elements[key]
+	if !ok {
+		c.m.Unlock()
+		if c.callbacks.OnCacheMiss != nil {
+			c.callbacks.OnCacheMiss(key)
+		}
+		return nil, ErrElementNotfound
+	}
+
+	if e.pinned == 0 && e.exp > 0 && e.exp < time.Now().Unix() {
+		delete(c.elements, key)
+		c.pol.forget(key)
+		c.size -= len(e.blob)
+		c.m.Unlock()
+		if c.callbacks.OnCacheMiss != nil {
+			c.callbacks.OnCacheMiss(key)
 		}
-		c.recency.MoveToFront(elem)
-		return bytes.NewReader(elem.Value.(cacheElem).blob), nil
+		return nil, ErrElementNotfound
+	}
+	c.pol.touch(key, len(e.blob))
+	blob := e.blob
+	c.m.Unlock()
+
+	if c.callbacks.OnCacheHit != nil {
+		c.callbacks.OnCacheHit(key)
+	}
+	return bytes.NewReader(blob), nil
+}
+
+// Pin marks the entry for key as non-evictable. Pins nest: an entry stays
+// pinned until Unpin has been called once for every Pin. It returns
+// ErrElementNotfound if key is not present.
+func (c *cache) Pin(key string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	e, ok := c.elements[key]
+	if !ok {
+		return ErrElementNotfound
+	}
+	e.pinned++
+	return nil
+}
+
+// Unpin releases one pin placed by Pin. It is a no-op if key is not present
+// or not currently pinned.
+func (c *cache) Unpin(key string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	e, ok := c.elements[key]
+	if !ok {
+		return
+	}
+	if e.pinned > 0 {
+		e.pinned--
 	}
-	return nil, ErrElementNotfound
 }
 
 func mrproper(c *cache, interval time.Duration) {
@@ -79,14 +196,45 @@ func mrproper(c *cache, interval time.Duration) {
 		case <-timer.C:
 			now := time.Now().Unix()
 			c.m.Lock()
-			for e := c.recency.Front(); e != nil; e = e.Next() {
-				if e.Value.(cacheElem).exp < now {
-					delete(c.elements, e.Value.(cacheElem).key)
-					c.recency.Remove(e)
-					c.size -= len(e.Value.(cacheElem).blob)
+			var expired []expiringElem
+			for _, e := range c.elements {
+				if e.pinned == 0 && e.exp < now {
+					// Snapshot the fields we need while still holding the
+					// lock: e itself stays live and mutable under Store, so
+					// reading e.blob/e.exp after unlocking would race.
+					expired = append(expired, expiringElem{ptr: e, key: e.key, blob: e.blob})
 				}
 			}
 			c.m.Unlock()
+
+			// OnExpired runs outside the mutex so it can safely re-enter the
+			// cache (e.g. to Store a replacement) without deadlocking.
+			for _, se := range expired {
+				refreshed := false
+				if c.callbacks.OnExpired != nil {
+					newData, newTTL := c.callbacks.OnExpired(se.key, se.blob)
+					if newTTL > 0 {
+						c.m.Lock()
+						if cur, ok := c.elements[se.key]; ok && cur == se.ptr {
+							c.size += len(newData) - len(cur.blob)
+							cur.blob = newData
+							cur.exp = time.Now().Add(newTTL).Unix()
+							c.pol.resize(se.key, len(newData))
+						}
+						c.m.Unlock()
+						refreshed = true
+					}
+				}
+				if !refreshed {
+					c.m.Lock()
+					if cur, ok := c.elements[se.key]; ok && cur == se.ptr {
+						delete(c.elements, se.key)
+						c.pol.forget(se.key)
+						c.size -= len(cur.blob)
+					}
+					c.m.Unlock()
+				}
+			}
 			timer.Reset(interval)
 		}
 	}
@@ -103,12 +251,22 @@ func New(conf Configuration) *Cache {
 	}
 
 	c := &cache{
-		elements:      make(map[string]*list.Element),
-		recency:       list.New(),
+		elements:      make(map[string]*cacheElem),
 		m:             &sync.Mutex{},
 		maxSize:       conf.MaxSize,
 		defaultExpire: conf.DefaultExpire,
 		done:          make(chan struct{}),
+		callbacks:     conf.Callbacks,
+		loadWaiters:   make(map[string]*loadWaiter),
+	}
+
+	switch conf.Policy {
+	case Policy2Q:
+		c.pol = newTwoQPolicy(conf.MaxSize)
+	case PolicyARC:
+		c.pol = newARCPolicy()
+	default:
+		c.pol = newLRUPolicy()
 	}
 
 	C := &Cache{c}
@@ -127,4 +285,28 @@ type Configuration struct {
 	MaxSize       int
 	DefaultExpire time.Duration
 	CleanInterval time.Duration
+	Callbacks     Callbacks
+	// Policy selects the eviction strategy. Defaults to PolicyLRU.
+	Policy Policy
+}
+
+// Callbacks are optional hooks invoked by Store, Get, and the expiration
+// janitor. They are always called outside the cache's mutex, so it is safe
+// for them to re-enter the cache (e.g. Store a replacement from OnEvicted).
+type Callbacks struct {
+	// OnEvicted is called after an entry is evicted to make room for a new
+	// Store.
+	OnEvicted func(key string, blob []byte)
+	// OnExpired is called when the janitor finds an entry past its
+	// expiration. If it returns newTTL > 0, the entry is refreshed in place
+	// with newData instead of being removed, without disturbing its
+	// position in the recency list.
+	OnExpired func(key string, blob []byte) (newData []byte, newTTL time.Duration)
+	// OnCacheHit is called after a successful Get.
+	OnCacheHit func(key string)
+	// OnCacheMiss is called after a Get that found nothing (absent or
+	// expired).
+	OnCacheMiss func(key string)
+	// OnAfterPut is called after Store has inserted data under key.
+	OnAfterPut func(key string, blob []byte)
 }
@@ -0,0 +1,178 @@
+package lrucache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreGetRoundTrip(t *testing.T) {
+	c := New(Configuration{MaxSize: 1024})
+
+	if err := c.Store("a", []byte("hello")); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	r, err := c.Get("a")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	buf := make([]byte, 5)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(buf) != "hello" {
+		t.Fatalf("got %q, want %q", buf, "hello")
+	}
+}
+
+func TestStoreEvictsUnpinnedInLRUOrder(t *testing.T) {
+	c := New(Configuration{MaxSize: 10})
+
+	if err := c.Store("a", []byte("12345")); err != nil {
+		t.Fatalf("Store a: %v", err)
+	}
+	if err := c.Store("b", []byte("12345")); err != nil {
+		t.Fatalf("Store b: %v", err)
+	}
+	// "a" is now the least recently used; storing "c" should evict it.
+	if err := c.Store("c", []byte("12345")); err != nil {
+		t.Fatalf("Store c: %v", err)
+	}
+	if _, err := c.Get("a"); err != ErrElementNotfound {
+		t.Fatalf("Get a: got %v, want ErrElementNotfound", err)
+	}
+	if _, err := c.Get("b"); err != nil {
+		t.Fatalf("Get b: %v", err)
+	}
+}
+
+func TestStoreItemTooLarge(t *testing.T) {
+	c := New(Configuration{MaxSize: 4})
+	if err := c.Store("a", []byte("12345")); err != ErrCacheItemTooLarge {
+		t.Fatalf("got %v, want ErrCacheItemTooLarge", err)
+	}
+}
+
+func TestPinPreventsEviction(t *testing.T) {
+	c := New(Configuration{MaxSize: 10})
+
+	if err := c.Store("a", []byte("12345")); err != nil {
+		t.Fatalf("Store a: %v", err)
+	}
+	if err := c.Pin("a"); err != nil {
+		t.Fatalf("Pin a: %v", err)
+	}
+	// "a" is the only resident entry and it is pinned, so there is nothing
+	// to evict to make room for "b".
+	if err := c.Store("b", []byte("123456")); err != ErrCacheFull {
+		t.Fatalf("got %v, want ErrCacheFull", err)
+	}
+
+	c.Unpin("a")
+	if err := c.Store("b", []byte("123456")); err != nil {
+		t.Fatalf("Store b after Unpin: %v", err)
+	}
+	if _, err := c.Get("a"); err != ErrElementNotfound {
+		t.Fatalf("Get a: got %v, want ErrElementNotfound", err)
+	}
+}
+
+// TestStoreFailureDoesNotLoseUnpinnedVictims reproduces a bug where Store
+// evicted unpinned victims one at a time as it went, so a pinned entry
+// discovered only partway through the loop left already-evicted entries
+// permanently gone even though the Store call itself failed with
+// ErrCacheFull.
+func TestStoreFailureDoesNotLoseUnpinnedVictims(t *testing.T) {
+	c := New(Configuration{MaxSize: 10})
+
+	if err := c.Store("a", []byte("12345")); err != nil {
+		t.Fatalf("Store a: %v", err)
+	}
+	if err := c.Pin("a"); err != nil {
+		t.Fatalf("Pin a: %v", err)
+	}
+	if err := c.Store("b", []byte("12345")); err != nil {
+		t.Fatalf("Store b: %v", err)
+	}
+
+	// "a" is pinned, so evicting "b" alone can't free the 9 bytes needed;
+	// Store must fail without discarding "b".
+	if err := c.Store("c", []byte("123456789")); err != ErrCacheFull {
+		t.Fatalf("got %v, want ErrCacheFull", err)
+	}
+	if _, err := c.Get("b"); err != nil {
+		t.Fatalf("Get b after failed Store: %v, want b to still be present", err)
+	}
+}
+
+// TestExpiryRefreshConcurrentStoreRace exercises mrproper's OnExpired
+// refresh path concurrently with Store on the same key, reproducing the
+// race a -race build would catch if mrproper read a *cacheElem's fields
+// after releasing the cache lock.
+func TestExpiryRefreshConcurrentStoreRace(t *testing.T) {
+	c := New(Configuration{
+		MaxSize:       1024,
+		CleanInterval: time.Millisecond,
+		Callbacks: Callbacks{
+			OnExpired: func(key string, blob []byte) ([]byte, time.Duration) {
+				return blob, time.Hour
+			},
+		},
+	})
+
+	if err := c.Store("a", []byte("x"), -time.Hour); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = c.Store("a", []byte("yy"), -time.Hour)
+			}
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(done)
+	wg.Wait()
+}
+
+// TestExpiryRefreshDoesNotDisturbRecency reproduces a bug where mrproper's
+// refresh path called pol.touch, which reorders recency the same as a Get or
+// Store would. OnExpired documents that a refresh leaves the entry's
+// position in the recency list untouched, so "a" (refreshed) must stay
+// behind "b" (merely stored once, more recently) rather than jumping ahead
+// of it.
+func TestExpiryRefreshDoesNotDisturbRecency(t *testing.T) {
+	c := New(Configuration{
+		MaxSize:       1024,
+		CleanInterval: time.Millisecond,
+		Callbacks: Callbacks{
+			OnExpired: func(key string, blob []byte) ([]byte, time.Duration) {
+				return blob, time.Hour
+			},
+		},
+	})
+
+	if err := c.Store("a", []byte("x"), -time.Hour); err != nil {
+		t.Fatalf("Store a: %v", err)
+	}
+	if err := c.Store("b", []byte("y"), time.Hour); err != nil {
+		t.Fatalf("Store b: %v", err)
+	}
+
+	// Wait for the janitor to observe "a" as expired and refresh it.
+	time.Sleep(50 * time.Millisecond)
+
+	keys := c.pol.keys()
+	if len(keys) != 2 || keys[0] != "b" || keys[1] != "a" {
+		t.Fatalf("pol.keys() = %v, want [b a] (refresh must not move a ahead of b)", keys)
+	}
+}
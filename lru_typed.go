@@ -0,0 +1,223 @@
+package lrucache
+
+import "sync"
+
+// SizeFunc reports the weight of a value for the purpose of capacity
+// accounting in a TypedCache. When omitted, every entry counts as 1,
+// turning MaxSize into an entry-count limit instead of a byte-size limit.
+type SizeFunc[V any] func(V) int
+
+// typedNode is a node in an intrusive doubly linked recency list backed by
+// a slice instead of container/list. Evicted nodes are pushed onto a free
+// list and reused by the next Add, so a cache that stays at capacity runs
+// without further allocation.
+type typedNode[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next int
+}
+
+const typedNone = -1
+
+// TypedCache is a generic LRU cache for values of type V keyed by K. Unlike
+// Cache, it stores values directly instead of boxing them into []byte.
+type TypedCache[K comparable, V any] struct {
+	m     sync.Mutex
+	nodes []typedNode[K, V]
+	index map[K]int
+	free  int
+	head  int
+	tail  int
+
+	size, maxSize int
+	sizeOf        SizeFunc[V]
+}
+
+// TypedConfiguration configures a TypedCache.
+type TypedConfiguration[V any] struct {
+	// MaxSize is the capacity of the cache, measured in whatever unit SizeOf
+	// returns. Defaults to 1024 entries if left zero.
+	MaxSize int
+	// SizeOf reports the weight of a value. Defaults to 1 per entry, i.e.
+	// count-based eviction.
+	SizeOf SizeFunc[V]
+}
+
+// NewTyped creates a new TypedCache with a maximum size and optional value
+// sizing function.
+func NewTyped[K comparable, V any](conf TypedConfiguration[V]) *TypedCache[K, V] {
+	if conf.MaxSize <= 0 {
+		conf.MaxSize = 1024
+	}
+	sizeOf := conf.SizeOf
+	if sizeOf == nil {
+		sizeOf = func(V) int { return 1 }
+	}
+
+	return &TypedCache[K, V]{
+		index:   make(map[K]int),
+		free:    typedNone,
+		head:    typedNone,
+		tail:    typedNone,
+		maxSize: conf.MaxSize,
+		sizeOf:  sizeOf,
+	}
+}
+
+// unlink removes node i from the recency list without releasing it.
+func (c *TypedCache[K, V]) unlink(i int) {
+	n := &c.nodes[i]
+	if n.prev != typedNone {
+		c.nodes[n.prev].next = n.next
+	} else {
+		c.head = n.next
+	}
+	if n.next != typedNone {
+		c.nodes[n.next].prev = n.prev
+	} else {
+		c.tail = n.prev
+	}
+	n.prev, n.next = typedNone, typedNone
+}
+
+// pushFront inserts node i at the head of the recency list.
+func (c *TypedCache[K, V]) pushFront(i int) {
+	n := &c.nodes[i]
+	n.prev = typedNone
+	n.next = c.head
+	if c.head != typedNone {
+		c.nodes[c.head].prev = i
+	}
+	c.head = i
+	if c.tail == typedNone {
+		c.tail = i
+	}
+}
+
+// allocNode returns the index of a node ready to hold key/value, reusing a
+// freed node if one is available instead of growing nodes.
+func (c *TypedCache[K, V]) allocNode(key K, value V) int {
+	if c.free != typedNone {
+		i := c.free
+		c.free = c.nodes[i].next
+		c.nodes[i] = typedNode[K, V]{key: key, value: value, prev: typedNone, next: typedNone}
+		return i
+	}
+	c.nodes = append(c.nodes, typedNode[K, V]{key: key, value: value, prev: typedNone, next: typedNone})
+	return len(c.nodes) - 1
+}
+
+// releaseNode clears node i's payload and returns it to the free list.
+func (c *TypedCache[K, V]) releaseNode(i int) {
+	var zero V
+	c.nodes[i].value = zero
+	c.nodes[i].next = c.free
+	c.free = i
+}
+
+func (c *TypedCache[K, V]) evictOldest() {
+	i := c.tail
+	c.unlink(i)
+	delete(c.index, c.nodes[i].key)
+	c.size -= c.sizeOf(c.nodes[i].value)
+	c.releaseNode(i)
+}
+
+// Add inserts or updates the value for key, evicting from the back of the
+// recency list until the cache fits within MaxSize. The entry for key itself
+// is never evicted, so a single value larger than MaxSize stays and leaves
+// the cache over MaxSize.
+func (c *TypedCache[K, V]) Add(key K, value V) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if i, ok := c.index[key]; ok {
+		c.size += c.sizeOf(value) - c.sizeOf(c.nodes[i].value)
+		c.nodes[i].value = value
+		c.unlink(i)
+		c.pushFront(i)
+	} else {
+		i := c.allocNode(key, value)
+		c.index[key] = i
+		c.pushFront(i)
+		c.size += c.sizeOf(value)
+	}
+
+	for c.size > c.maxSize && len(c.index) > 1 {
+		c.evictOldest()
+	}
+}
+
+// Get returns the value for key and moves it to the front of the recency
+// list. The second return value is false if key is not present.
+func (c *TypedCache[K, V]) Get(key K) (V, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	i, ok := c.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.unlink(i)
+	c.pushFront(i)
+	return c.nodes[i].value, true
+}
+
+// Peek returns the value for key without affecting its recency.
+func (c *TypedCache[K, V]) Peek(key K) (V, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	i, ok := c.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return c.nodes[i].value, true
+}
+
+// Contains reports whether key is present without affecting its recency.
+func (c *TypedCache[K, V]) Contains(key K) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	_, ok := c.index[key]
+	return ok
+}
+
+// Remove deletes key from the cache, reporting whether it was present.
+func (c *TypedCache[K, V]) Remove(key K) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	i, ok := c.index[key]
+	if !ok {
+		return false
+	}
+	c.unlink(i)
+	delete(c.index, key)
+	c.size -= c.sizeOf(c.nodes[i].value)
+	c.releaseNode(i)
+	return true
+}
+
+// Keys returns all keys currently in the cache, most recently used first.
+func (c *TypedCache[K, V]) Keys() []K {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	keys := make([]K, 0, len(c.index))
+	for i := c.head; i != typedNone; i = c.nodes[i].next {
+		keys = append(keys, c.nodes[i].key)
+	}
+	return keys
+}
+
+// Len returns the number of entries currently in the cache.
+func (c *TypedCache[K, V]) Len() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return len(c.index)
+}
@@ -0,0 +1,149 @@
+package lrucache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTypedAddGetRoundTrip(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 10})
+
+	c.Add("a", 1)
+	v, ok := c.Get("a")
+	if !ok || v != 1 {
+		t.Fatalf("Get a = (%d, %v), want (1, true)", v, ok)
+	}
+}
+
+func TestTypedAddUpdatesExistingKey(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 10})
+
+	c.Add("a", 1)
+	c.Add("a", 2)
+	if c.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", c.Len())
+	}
+	v, ok := c.Get("a")
+	if !ok || v != 2 {
+		t.Fatalf("Get a = (%d, %v), want (2, true)", v, ok)
+	}
+}
+
+func TestTypedDefaultSizeOfIsCountBased(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 2})
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+
+	if c.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", c.Len())
+	}
+	if c.Contains("a") {
+		t.Fatalf("expected %q to be evicted", "a")
+	}
+}
+
+func TestTypedAddEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 2})
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	// Touch "a" so "b" becomes the least recently used.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("Get a: expected present")
+	}
+	c.Add("c", 3)
+
+	if c.Contains("b") {
+		t.Fatalf("expected %q to be evicted", "b")
+	}
+	if !c.Contains("a") || !c.Contains("c") {
+		t.Fatalf("expected a and c to still be present")
+	}
+}
+
+func TestTypedPeekDoesNotAffectRecency(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 2})
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if _, ok := c.Peek("a"); !ok {
+		t.Fatalf("Peek a: expected present")
+	}
+	c.Add("c", 3)
+
+	if c.Contains("a") {
+		t.Fatalf("expected %q to be evicted (Peek must not affect recency)", "a")
+	}
+}
+
+func TestTypedRemove(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 10})
+
+	c.Add("a", 1)
+	if !c.Remove("a") {
+		t.Fatalf("Remove a: expected true")
+	}
+	if c.Remove("a") {
+		t.Fatalf("Remove a again: expected false")
+	}
+	if c.Contains("a") {
+		t.Fatalf("expected %q to be gone", "a")
+	}
+}
+
+func TestTypedKeysMostRecentlyUsedFirst(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 10})
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+
+	keys := c.Keys()
+	want := []string{"c", "b", "a"}
+	if len(keys) != len(want) {
+		t.Fatalf("Keys() = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("Keys() = %v, want %v", keys, want)
+		}
+	}
+}
+
+func TestTypedSizeOfWeightsEviction(t *testing.T) {
+	c := NewTyped[string, string](TypedConfiguration[string]{
+		MaxSize: 10,
+		SizeOf:  func(v string) int { return len(v) },
+	})
+
+	c.Add("a", "12345")
+	c.Add("b", "12345")
+	// "a" is now the least recently used; adding "c" needs room for 5 more
+	// bytes, which only evicting "a" can free.
+	c.Add("c", "12345")
+
+	if c.Contains("a") {
+		t.Fatalf("expected %q to be evicted", "a")
+	}
+	if !c.Contains("b") || !c.Contains("c") {
+		t.Fatalf("expected b and c to still be present")
+	}
+}
+
+func TestTypedReusesFreedNodesWithoutGrowing(t *testing.T) {
+	c := NewTyped[string, int](TypedConfiguration[int]{MaxSize: 1})
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	steadyState := len(c.nodes)
+
+	for i := 0; i < 50; i++ {
+		c.Add(fmt.Sprintf("k%d", i), i)
+	}
+
+	if got := len(c.nodes); got != steadyState {
+		t.Fatalf("len(nodes) = %d, want %d (freed nodes should be reused, not leaked)", got, steadyState)
+	}
+}
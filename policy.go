@@ -0,0 +1,101 @@
+package lrucache
+
+import "container/list"
+
+// Policy selects which eviction strategy a Cache uses.
+type Policy int
+
+const (
+	// PolicyLRU evicts the least recently used entry. This is the default.
+	PolicyLRU Policy = iota
+	// Policy2Q uses the 2Q algorithm, which resists cache pollution from
+	// long scans by only promoting a key to the frequent set once it has
+	// been accessed a second time.
+	Policy2Q
+	// PolicyARC uses the Adaptive Replacement Cache algorithm, which tunes
+	// the balance between recency and frequency based on observed hit
+	// patterns instead of a fixed ratio.
+	PolicyARC
+)
+
+// policy decides which key to evict next and tracks how Store/Get affect
+// recency, independently of where a key's blob is actually stored. cache
+// owns the blobs (in c.elements) and only asks the policy for an ordering.
+type policy interface {
+	// insert records a brand-new key of the given byte size.
+	insert(key string, size int)
+	// touch records an access to key (a Get hit, or a Store that updates an
+	// existing key) of the given byte size.
+	touch(key string, size int)
+	// resize updates key's accounted byte size without affecting its
+	// recency, e.g. when OnExpired refreshes a blob in place.
+	resize(key string, size int)
+	// forget removes key from the policy's bookkeeping without any ghost
+	// tracking, e.g. after expiry.
+	forget(key string)
+	// evicted removes key from the policy's bookkeeping because the cache
+	// evicted it to make room for a new Store. Policies that keep ghost
+	// entries (2Q, ARC) use this as the hook to record one.
+	evicted(key string)
+	// victim returns the next key the policy would evict, skipping any key
+	// for which evictable returns false. ok is false if none remain.
+	victim(evictable func(key string) bool) (key string, ok bool)
+	// keys returns all resident (non-ghost) keys, most valuable first, for
+	// snapshotting.
+	keys() []string
+}
+
+// lruPolicy is the default policy: evict whatever was used least recently.
+type lruPolicy struct {
+	order *list.List
+	index map[string]*list.Element
+}
+
+func newLRUPolicy() *lruPolicy {
+	return &lruPolicy{
+		order: list.New(),
+		index: make(map[string]*list.Element),
+	}
+}
+
+func (p *lruPolicy) insert(key string, _ int) {
+	p.index[key] = p.order.PushFront(key)
+}
+
+func (p *lruPolicy) touch(key string, _ int) {
+	if e, ok := p.index[key]; ok {
+		p.order.MoveToFront(e)
+	}
+}
+
+// resize is a no-op: lruPolicy doesn't track byte sizes, only order.
+func (p *lruPolicy) resize(key string, size int) {}
+
+func (p *lruPolicy) forget(key string) {
+	if e, ok := p.index[key]; ok {
+		p.order.Remove(e)
+		delete(p.index, key)
+	}
+}
+
+func (p *lruPolicy) evicted(key string) {
+	p.forget(key)
+}
+
+func (p *lruPolicy) victim(evictable func(key string) bool) (string, bool) {
+	for e := p.order.Back(); e != nil; e = e.Prev() {
+		key := e.Value.(string)
+		if evictable(key) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func (p *lruPolicy) keys() []string {
+	keys := make([]string, 0, p.order.Len())
+	for e := p.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(string))
+	}
+	return keys
+}
@@ -0,0 +1,186 @@
+package lrucache
+
+import "container/list"
+
+// Default2QRecentRatio is the fraction of MaxSize (in bytes) budgeted for
+// the recent list. While recent holds more than this, eviction takes from
+// recent first; keys move to the frequent list only on a second access.
+const Default2QRecentRatio = 0.25
+
+// Default2QGhostRatio is the fraction of MaxSize the ghost list is allowed
+// to account for, expressed in entries rather than bytes: ghost entries
+// remember an evicted key but not its blob, so there's nothing to size
+// them in bytes by. assumedGhostEntrySize converts the byte-denominated
+// MaxSize into an entry budget; it's a deliberately small assumption so
+// tiny caches still get a usable ghost list, and large caches scale up
+// instead of being capped at a fixed count.
+const (
+	Default2QGhostRatio   = 0.5
+	assumedGhostEntrySize = 64
+	minGhostEntries       = 16
+)
+
+type twoQItem struct {
+	key  string
+	size int
+}
+
+// twoQPolicy implements the 2Q algorithm: a scan through `recent` once is
+// not enough to earn a key a place in `frequent`, which makes it resistant
+// to cache pollution from one-off sequential access patterns.
+type twoQPolicy struct {
+	recent    *list.List // A1in
+	recentIdx map[string]*list.Element
+
+	frequent    *list.List // Am
+	frequentIdx map[string]*list.Element
+
+	ghost    *list.List // A1out: evicted recent keys, no blobs
+	ghostIdx map[string]*list.Element
+	ghostCap int
+
+	recentBytes, recentBudget     int
+	frequentBytes, frequentBudget int
+}
+
+func newTwoQPolicy(maxSize int) *twoQPolicy {
+	recentBudget := int(float64(maxSize) * Default2QRecentRatio)
+	ghostCap := int(float64(maxSize) * Default2QGhostRatio / assumedGhostEntrySize)
+	if ghostCap < minGhostEntries {
+		ghostCap = minGhostEntries
+	}
+	return &twoQPolicy{
+		recent:         list.New(),
+		recentIdx:      make(map[string]*list.Element),
+		frequent:       list.New(),
+		frequentIdx:    make(map[string]*list.Element),
+		ghost:          list.New(),
+		ghostIdx:       make(map[string]*list.Element),
+		ghostCap:       ghostCap,
+		recentBudget:   recentBudget,
+		frequentBudget: maxSize - recentBudget,
+	}
+}
+
+func (p *twoQPolicy) insert(key string, size int) {
+	if e, ok := p.ghostIdx[key]; ok {
+		// A key that scanned through before and is seen again has proven
+		// itself worth keeping: promote it straight into frequent.
+		p.ghost.Remove(e)
+		delete(p.ghostIdx, key)
+		elem := p.frequent.PushFront(twoQItem{key, size})
+		p.frequentIdx[key] = elem
+		p.frequentBytes += size
+		return
+	}
+	elem := p.recent.PushFront(twoQItem{key, size})
+	p.recentIdx[key] = elem
+	p.recentBytes += size
+}
+
+func (p *twoQPolicy) touch(key string, size int) {
+	if e, ok := p.recentIdx[key]; ok {
+		p.recent.Remove(e)
+		delete(p.recentIdx, key)
+		p.recentBytes -= e.Value.(twoQItem).size
+
+		fe := p.frequent.PushFront(twoQItem{key, size})
+		p.frequentIdx[key] = fe
+		p.frequentBytes += size
+		return
+	}
+	if e, ok := p.frequentIdx[key]; ok {
+		p.frequentBytes += size - e.Value.(twoQItem).size
+		e.Value = twoQItem{key, size}
+		p.frequent.MoveToFront(e)
+	}
+}
+
+// resize updates key's accounted byte size in whichever list currently
+// holds it, without moving it within that list.
+func (p *twoQPolicy) resize(key string, size int) {
+	if e, ok := p.recentIdx[key]; ok {
+		p.recentBytes += size - e.Value.(twoQItem).size
+		e.Value = twoQItem{key, size}
+		return
+	}
+	if e, ok := p.frequentIdx[key]; ok {
+		p.frequentBytes += size - e.Value.(twoQItem).size
+		e.Value = twoQItem{key, size}
+	}
+}
+
+func (p *twoQPolicy) forget(key string) {
+	if e, ok := p.recentIdx[key]; ok {
+		p.recent.Remove(e)
+		delete(p.recentIdx, key)
+		p.recentBytes -= e.Value.(twoQItem).size
+		return
+	}
+	if e, ok := p.frequentIdx[key]; ok {
+		p.frequent.Remove(e)
+		delete(p.frequentIdx, key)
+		p.frequentBytes -= e.Value.(twoQItem).size
+		return
+	}
+	if e, ok := p.ghostIdx[key]; ok {
+		p.ghost.Remove(e)
+		delete(p.ghostIdx, key)
+	}
+}
+
+func (p *twoQPolicy) evicted(key string) {
+	if e, ok := p.recentIdx[key]; ok {
+		p.recent.Remove(e)
+		delete(p.recentIdx, key)
+		p.recentBytes -= e.Value.(twoQItem).size
+		p.pushGhost(key)
+		return
+	}
+	if e, ok := p.frequentIdx[key]; ok {
+		p.frequent.Remove(e)
+		delete(p.frequentIdx, key)
+		p.frequentBytes -= e.Value.(twoQItem).size
+	}
+}
+
+func (p *twoQPolicy) pushGhost(key string) {
+	p.ghostIdx[key] = p.ghost.PushFront(key)
+	for p.ghost.Len() > p.ghostCap {
+		back := p.ghost.Back()
+		p.ghost.Remove(back)
+		delete(p.ghostIdx, back.Value.(string))
+	}
+}
+
+func (p *twoQPolicy) victim(evictable func(key string) bool) (string, bool) {
+	first, second := p.frequent, p.recent
+	if p.recentBytes > p.recentBudget || p.frequentBytes <= p.frequentBudget {
+		first, second = p.recent, p.frequent
+	}
+	if key, ok := scanTwoQBack(first, evictable); ok {
+		return key, true
+	}
+	return scanTwoQBack(second, evictable)
+}
+
+func (p *twoQPolicy) keys() []string {
+	keys := make([]string, 0, p.frequent.Len()+p.recent.Len())
+	for e := p.frequent.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(twoQItem).key)
+	}
+	for e := p.recent.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(twoQItem).key)
+	}
+	return keys
+}
+
+func scanTwoQBack(l *list.List, evictable func(string) bool) (string, bool) {
+	for e := l.Back(); e != nil; e = e.Prev() {
+		key := e.Value.(twoQItem).key
+		if evictable(key) {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,40 @@
+package lrucache
+
+import "testing"
+
+func TestTwoQGhostCapacityScalesWithMaxSize(t *testing.T) {
+	small := newTwoQPolicy(1024)
+	large := newTwoQPolicy(1024 * 1024)
+
+	if small.ghostCap < minGhostEntries {
+		t.Fatalf("small.ghostCap = %d, want >= %d", small.ghostCap, minGhostEntries)
+	}
+	if large.ghostCap <= small.ghostCap {
+		t.Fatalf("large.ghostCap = %d, want > small.ghostCap = %d", large.ghostCap, small.ghostCap)
+	}
+}
+
+func TestTwoQPromotesGhostHitToFrequent(t *testing.T) {
+	c := New(Configuration{MaxSize: 20, Policy: Policy2Q})
+
+	if err := c.Store("a", []byte("12345")); err != nil {
+		t.Fatalf("Store a: %v", err)
+	}
+	// Evict "a" out of recent into the ghost list.
+	if err := c.Store("b", []byte("1234567890123456")); err != nil {
+		t.Fatalf("Store b: %v", err)
+	}
+	if _, err := c.Get("a"); err != ErrElementNotfound {
+		t.Fatalf("Get a: got %v, want ErrElementNotfound", err)
+	}
+
+	// Re-storing "a" should hit the ghost entry and promote straight into
+	// frequent instead of starting over in recent.
+	if err := c.Store("a", []byte("12345")); err != nil {
+		t.Fatalf("Store a again: %v", err)
+	}
+	pol := c.pol.(*twoQPolicy)
+	if _, ok := pol.frequentIdx["a"]; !ok {
+		t.Fatalf("expected %q to be promoted into frequent after a ghost hit", "a")
+	}
+}
@@ -0,0 +1,176 @@
+package lrucache
+
+import "container/list"
+
+// arcPolicy implements a simplified Adaptive Replacement Cache: two resident
+// lists, T1 (seen once) and T2 (seen at least twice), each backed by a ghost
+// list of evicted keys, B1 and B2. A hit in a ghost list nudges the target
+// size p of T1 up or down, so the recency/frequency balance is learned from
+// the actual access pattern instead of fixed ahead of time like 2Q's ratio.
+//
+// Unlike the textbook algorithm, which targets a fixed page count c, this
+// policy tracks c as the live entry count (|T1|+|T2|), since Cache bounds
+// itself by bytes rather than a fixed number of entries.
+type arcPolicy struct {
+	t1, t2, b1, b2             *list.List
+	t1Idx, t2Idx, b1Idx, b2Idx map[string]*list.Element
+
+	p int // target size of T1, in entries
+}
+
+func newARCPolicy() *arcPolicy {
+	return &arcPolicy{
+		t1:    list.New(),
+		t2:    list.New(),
+		b1:    list.New(),
+		b2:    list.New(),
+		t1Idx: make(map[string]*list.Element),
+		t2Idx: make(map[string]*list.Element),
+		b1Idx: make(map[string]*list.Element),
+		b2Idx: make(map[string]*list.Element),
+	}
+}
+
+func (p *arcPolicy) insert(key string, _ int) {
+	if e, ok := p.b1Idx[key]; ok {
+		delta := 1
+		if p.b1.Len() > 0 && p.b2.Len() > p.b1.Len() {
+			delta = p.b2.Len() / p.b1.Len()
+		}
+		p.p += delta
+		if c := p.t1.Len() + p.t2.Len() + 1; p.p > c {
+			p.p = c
+		}
+		p.b1.Remove(e)
+		delete(p.b1Idx, key)
+		elem := p.t2.PushFront(key)
+		p.t2Idx[key] = elem
+		return
+	}
+	if e, ok := p.b2Idx[key]; ok {
+		delta := 1
+		if p.b2.Len() > 0 && p.b1.Len() > p.b2.Len() {
+			delta = p.b1.Len() / p.b2.Len()
+		}
+		p.p -= delta
+		if p.p < 0 {
+			p.p = 0
+		}
+		p.b2.Remove(e)
+		delete(p.b2Idx, key)
+		elem := p.t2.PushFront(key)
+		p.t2Idx[key] = elem
+		return
+	}
+
+	elem := p.t1.PushFront(key)
+	p.t1Idx[key] = elem
+	p.trimGhosts()
+}
+
+func (p *arcPolicy) touch(key string, _ int) {
+	if e, ok := p.t1Idx[key]; ok {
+		// A second access earns promotion from T1 (seen once) to T2.
+		p.t1.Remove(e)
+		delete(p.t1Idx, key)
+		elem := p.t2.PushFront(key)
+		p.t2Idx[key] = elem
+		return
+	}
+	if e, ok := p.t2Idx[key]; ok {
+		p.t2.MoveToFront(e)
+	}
+}
+
+// resize is a no-op: arcPolicy doesn't track byte sizes, only recency/frequency.
+func (p *arcPolicy) resize(key string, size int) {}
+
+func (p *arcPolicy) forget(key string) {
+	if e, ok := p.t1Idx[key]; ok {
+		p.t1.Remove(e)
+		delete(p.t1Idx, key)
+		return
+	}
+	if e, ok := p.t2Idx[key]; ok {
+		p.t2.Remove(e)
+		delete(p.t2Idx, key)
+		return
+	}
+	if e, ok := p.b1Idx[key]; ok {
+		p.b1.Remove(e)
+		delete(p.b1Idx, key)
+		return
+	}
+	if e, ok := p.b2Idx[key]; ok {
+		p.b2.Remove(e)
+		delete(p.b2Idx, key)
+	}
+}
+
+func (p *arcPolicy) evicted(key string) {
+	if e, ok := p.t1Idx[key]; ok {
+		p.t1.Remove(e)
+		delete(p.t1Idx, key)
+		p.b1Idx[key] = p.b1.PushFront(key)
+		p.trimGhosts()
+		return
+	}
+	if e, ok := p.t2Idx[key]; ok {
+		p.t2.Remove(e)
+		delete(p.t2Idx, key)
+		p.b2Idx[key] = p.b2.PushFront(key)
+		p.trimGhosts()
+	}
+}
+
+// trimGhosts keeps |B1|+|B2| from growing past the live entry count, dropping
+// the oldest ghost of the longer list (of B2 when both are the same length).
+func (p *arcPolicy) trimGhosts() {
+	c := p.t1.Len() + p.t2.Len()
+	for p.b1.Len()+p.b2.Len() > c {
+		switch {
+		case p.b1.Len() > p.b2.Len():
+			e := p.b1.Back()
+			p.b1.Remove(e)
+			delete(p.b1Idx, e.Value.(string))
+		case p.b2.Len() > 0:
+			e := p.b2.Back()
+			p.b2.Remove(e)
+			delete(p.b2Idx, e.Value.(string))
+		default:
+			return
+		}
+	}
+}
+
+func (p *arcPolicy) victim(evictable func(key string) bool) (string, bool) {
+	first, second := p.t2, p.t1
+	if p.t1.Len() > 0 && p.t1.Len() >= p.p {
+		first, second = p.t1, p.t2
+	}
+	if key, ok := scanStringBack(first, evictable); ok {
+		return key, true
+	}
+	return scanStringBack(second, evictable)
+}
+
+func (p *arcPolicy) keys() []string {
+	keys := make([]string, 0, p.t2.Len()+p.t1.Len())
+	for e := p.t2.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(string))
+	}
+	for e := p.t1.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(string))
+	}
+	return keys
+}
+
+func scanStringBack(l *list.List, evictable func(string) bool) (string, bool) {
+	for e := l.Back(); e != nil; e = e.Prev() {
+		key := e.Value.(string)
+		if evictable(key) {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package lrucache
+
+import "testing"
+
+func TestARCPromotesSecondAccessToT2(t *testing.T) {
+	p := newARCPolicy()
+
+	p.insert("a", 1)
+	if _, ok := p.t1Idx["a"]; !ok {
+		t.Fatalf("expected %q to be in T1 after first insert", "a")
+	}
+
+	p.touch("a", 1)
+	if _, ok := p.t2Idx["a"]; !ok {
+		t.Fatalf("expected %q to be promoted to T2 after a second access", "a")
+	}
+	if _, ok := p.t1Idx["a"]; ok {
+		t.Fatalf("expected %q to be removed from T1 after promotion", "a")
+	}
+}
+
+func TestARCEvictsFromT1BeforeT2ByDefault(t *testing.T) {
+	p := newARCPolicy()
+
+	p.insert("a", 1)
+	p.insert("b", 1)
+	p.touch("b", 1) // promote "b" into T2
+
+	victim, ok := p.victim(func(string) bool { return true })
+	if !ok {
+		t.Fatalf("victim: expected a candidate")
+	}
+	if victim != "a" {
+		t.Fatalf("victim = %q, want %q (T1 should be preferred while p is 0)", victim, "a")
+	}
+}
+
+func TestARCGhostHitInB1GrowsP(t *testing.T) {
+	p := newARCPolicy()
+
+	p.insert("a", 1)
+	// Keep T1 non-empty across the eviction below so trimGhosts doesn't
+	// immediately purge the ghost it just created.
+	p.insert("b", 1)
+	p.evicted("a")
+
+	if _, ok := p.b1Idx["a"]; !ok {
+		t.Fatalf("expected %q to be in B1 after eviction", "a")
+	}
+	if p.p != 0 {
+		t.Fatalf("p = %d before ghost hit, want 0", p.p)
+	}
+
+	// Re-inserting "a" should hit the B1 ghost, grow p, and land "a"
+	// directly in T2 instead of starting over in T1.
+	p.insert("a", 1)
+	if p.p == 0 {
+		t.Fatalf("p = 0 after B1 ghost hit, want > 0")
+	}
+	if _, ok := p.t2Idx["a"]; !ok {
+		t.Fatalf("expected %q to be promoted into T2 after a B1 ghost hit", "a")
+	}
+}
+
+func TestARCTrimGhostsCapsGhostLists(t *testing.T) {
+	p := newARCPolicy()
+
+	p.insert("a", 1)
+	p.evicted("a") // T1 -> B1, live entry count now 0
+
+	if p.b1.Len() != 0 {
+		t.Fatalf("b1.Len() = %d, want 0 (ghosts can't exceed the live entry count)", p.b1.Len())
+	}
+}
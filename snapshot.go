@@ -0,0 +1,121 @@
+package lrucache
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"time"
+)
+
+// snapshotEntry is the on-disk representation of a single cache entry.
+type snapshotEntry struct {
+	Key  string
+	Blob []byte
+	Exp  int64
+}
+
+// Save serializes the full cache state (keys, blobs, expirations, and
+// recency order) to w using encoding/gob.
+//
+// For Policy2Q and PolicyARC, only the flattened most-valuable-first key
+// order survives the round trip, not which tier (recent/frequent, or
+// T1/T2) each key was in: Load always re-inserts through policy.insert,
+// which is the "brand-new key" path for every policy. A cache restored
+// from a snapshot under one of these policies starts over with no
+// frequency history, the same as a freshly created one.
+func (c *cache) Save(w io.Writer) error {
+	c.m.Lock()
+	keys := c.pol.keys()
+	entries := make([]snapshotEntry, 0, len(keys))
+	for _, k := range keys {
+		e, ok := c.elements[k]
+		if !ok {
+			continue
+		}
+		entries = append(entries, snapshotEntry{Key: e.key, Blob: e.blob, Exp: e.exp})
+	}
+	c.m.Unlock()
+
+	return gob.NewEncoder(w).Encode(entries)
+}
+
+// Load restores entries previously written by Save. It respects the
+// current MaxSize, dropping the least valuable entries of the snapshot if
+// it no longer fits, and skips any entry whose absolute expiration has
+// already passed. See the note on Save about the frequency state
+// Policy2Q/PolicyARC lose across the round trip.
+func (c *cache) Load(r io.Reader) error {
+	var entries []snapshotEntry
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	// entries is ordered most valuable first; keep a prefix that fits
+	// within MaxSize and drop the rest.
+	kept := make([]snapshotEntry, 0, len(entries))
+	size := 0
+	for _, e := range entries {
+		if e.Exp > 0 && e.Exp < now {
+			continue
+		}
+		if size+len(e.Blob) > c.maxSize {
+			break
+		}
+		size += len(e.Blob)
+		kept = append(kept, e)
+	}
+
+	// Store the kept entries least valuable first, so the last Store call
+	// (the most valuable entry) ends up most recent.
+	for i := len(kept) - 1; i >= 0; i-- {
+		e := kept[i]
+		var err error
+		if e.Exp == 0 {
+			err = c.Store(e.Key, e.Blob)
+		} else {
+			err = c.Store(e.Key, e.Blob, time.Duration(e.Exp-now)*time.Second)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveFile writes a snapshot to path, writing to path+".tmp" and renaming
+// into place so a crash mid-write cannot corrupt an existing snapshot.
+func (c *cache) SaveFile(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Save(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadFile restores a snapshot previously written by SaveFile.
+func (c *cache) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
@@ -0,0 +1,39 @@
+package lrucache
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// streamWriter accumulates writes into a buffer and inserts them into the
+// cache as a single blob on Close.
+type streamWriter struct {
+	c   *cache
+	key string
+	exp []time.Duration
+	buf bytes.Buffer
+}
+
+// StoreStream returns a writer that accumulates data under key and, on
+// Close, atomically stores the completed blob. Optionally, the element will
+// expire after exp. This lets a caller stream an HTTP body or file straight
+// into the cache without first buffering it into a []byte of their own.
+//
+// If the accumulated size would exceed MaxSize, Write returns
+// ErrCacheItemTooLarge so the caller can abort early instead of buffering
+// data that Close would reject anyway.
+func (c *cache) StoreStream(key string, exp ...time.Duration) io.WriteCloser {
+	return &streamWriter{c: c, key: key, exp: exp}
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.c.maxSize {
+		return 0, ErrCacheItemTooLarge
+	}
+	return w.buf.Write(p)
+}
+
+func (w *streamWriter) Close() error {
+	return w.c.Store(w.key, w.buf.Bytes(), w.exp...)
+}
@@ -0,0 +1,61 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreStreamRoundTrip(t *testing.T) {
+	c := New(Configuration{MaxSize: 1024})
+
+	w := c.StoreStream("a", time.Hour)
+	if _, err := w.Write([]byte("hel")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Write([]byte("lo")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := c.Get("a")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	buf := make([]byte, 5)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(buf) != "hello" {
+		t.Fatalf("got %q, want %q", buf, "hello")
+	}
+}
+
+// TestStoreStreamWriteAbortsEarlyWhenTooLarge reproduces the early-abort
+// path: Write rejects data that would push the accumulated buffer past
+// MaxSize, instead of buffering it only for Close to reject later.
+func TestStoreStreamWriteAbortsEarlyWhenTooLarge(t *testing.T) {
+	c := New(Configuration{MaxSize: 4})
+
+	w := c.StoreStream("a")
+	if _, err := w.Write([]byte("12345")); err != ErrCacheItemTooLarge {
+		t.Fatalf("Write: got %v, want ErrCacheItemTooLarge", err)
+	}
+
+	if _, err := c.Get("a"); err != ErrElementNotfound {
+		t.Fatalf("Get a: got %v, want ErrElementNotfound", err)
+	}
+}
+
+func TestStoreStreamWriteAbortsOnceAccumulatedPastMaxSize(t *testing.T) {
+	c := New(Configuration{MaxSize: 4})
+
+	w := c.StoreStream("a")
+	if _, err := w.Write([]byte("12")); err != nil {
+		t.Fatalf("first Write: %v", err)
+	}
+	if _, err := w.Write([]byte("123")); err != ErrCacheItemTooLarge {
+		t.Fatalf("second Write: got %v, want ErrCacheItemTooLarge", err)
+	}
+}